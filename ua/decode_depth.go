@@ -0,0 +1,96 @@
+// Copyright 2018-2019 opcua authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ua
+
+import "sync"
+
+// DecodeOptions bounds how deeply Decode will follow a chain of nested
+// Variant or DiagnosticInfo values before giving up. A hostile or fuzzed
+// frame can otherwise encode a variant-of-variant (or
+// diagnosticinfo-of-diagnosticinfo) chain deep enough to overflow the
+// stack.
+// It backs the package-wide state mutated by SetMaxDecodeDepth; there is
+// no per-call or per-connection variant of it yet (see SetMaxDecodeDepth).
+type DecodeOptions struct {
+	// MaxDepth is the maximum nesting depth allowed.
+	MaxDepth int
+}
+
+var (
+	decodeDepthMu sync.RWMutex
+	decodeOptions = DecodeOptions{MaxDepth: 100}
+)
+
+// SetMaxDecodeDepth sets the package-wide maximum nesting depth for
+// Variant and DiagnosticInfo decoding. The default is 100.
+//
+// This only bounds the Variant-wrapping-Variant and
+// Variant-wrapping-DiagnosticInfo chains handled by Variant.decode in
+// ua/variant.go. DiagnosticInfo.Decode's own recursion into InnerDiagnosticInfo
+// lives in ua/diagnostic_info.go, which is not part of this change and
+// does not consult DecodeOptions; a self-referential DiagnosticInfo chain
+// that never passes through a Variant is not bounded by this guard. There
+// is also no per-connection override yet: client/server configuration
+// plumbs through types that aren't part of this change either, so for now
+// SetMaxDecodeDepth is the only knob, and it is process-wide.
+func SetMaxDecodeDepth(n int) {
+	decodeDepthMu.Lock()
+	defer decodeDepthMu.Unlock()
+	decodeOptions.MaxDepth = n
+}
+
+func currentMaxDecodeDepth() int {
+	decodeDepthMu.RLock()
+	defer decodeDepthMu.RUnlock()
+	return decodeOptions.MaxDepth
+}
+
+// CheckDecodeDepth reports ErrMaxDepthExceeded if depth+1, the depth a
+// caller is about to recurse to, would reach the configured maximum, so a
+// chain holds at most MaxDepth levels counting the outermost as the first.
+// Variant.decode uses this directly; it is exported so that
+// DiagnosticInfo.Decode's own recursion into InnerDiagnosticInfo (in
+// ua/diagnostic_info.go, not part of this change) can opt into the same
+// limit once it calls this before recursing. Until that call is added
+// there, a standalone DiagnosticInfo sent outside of a Variant is not
+// bounded by SetMaxDecodeDepth - only the Variant-wrapping cases handled
+// in ua/variant.go are.
+func CheckDecodeDepth(depth int) error {
+	if depth+1 >= currentMaxDecodeDepth() {
+		return ErrMaxDepthExceeded
+	}
+	return nil
+}
+
+// maxDepthError is returned by Decode when a chain of nested Variant or
+// DiagnosticInfo values exceeds the configured maximum nesting depth.
+type maxDepthError struct{}
+
+func (maxDepthError) Error() string {
+	return "opcua: maximum variant/diagnosticinfo nesting depth exceeded"
+}
+
+// StatusCode maps the error onto the wire status returned to callers that
+// surface decode failures as a StatusCode.
+func (maxDepthError) StatusCode() StatusCode {
+	return StatusBadDecodingError
+}
+
+// ErrMaxDepthExceeded is returned by Decode when the nesting depth of a
+// chain of Variant or DiagnosticInfo values would exceed the configured
+// maximum. It maps to StatusBadDecodingError.
+var ErrMaxDepthExceeded error = maxDepthError{}
+
+// nestedVariant adapts a *Variant so that buf.ReadStruct dispatches to its
+// depth-tracking decode method instead of resetting back to depth 0
+// through the public Decode method.
+type nestedVariant struct {
+	*Variant
+	depth int
+}
+
+func (n *nestedVariant) Decode(b []byte) (int, error) {
+	return n.Variant.decode(b, n.depth)
+}
@@ -0,0 +1,163 @@
+// Copyright 2018-2019 opcua authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ua
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// BinaryEncoder is implemented by user types that know how to encode
+// themselves to the binary body carried inside an ExtensionObject.
+type BinaryEncoder interface {
+	EncodeOPCUA() ([]byte, error)
+}
+
+// BinaryDecoder is implemented by user types that know how to decode
+// themselves from the binary body carried inside an ExtensionObject.
+type BinaryDecoder interface {
+	DecodeOPCUA([]byte) (int, error)
+}
+
+// extensionObjectType records the TypeID and factory an application
+// registered for one of its own Go types via RegisterExtensionObject.
+type extensionObjectType struct {
+	typeID  *NodeID
+	factory func() interface{}
+}
+
+var (
+	extTypesMu   sync.RWMutex
+	extTypesByID = map[string]extensionObjectType{}
+	extTypesByGo = map[reflect.Type]extensionObjectType{}
+)
+
+// RegisterExtensionObject associates typeID with factory, a function that
+// returns a new, empty value of a user-defined Go type. Once registered,
+// values of that type ride inside an ExtensionObject/Variant without
+// editing this codec: Variant.Encode wraps a matching Value in an
+// ExtensionObject carrying typeID, and Variant.Decode looks up a decoded
+// TypeExtensionObject variant's TypeID and unwraps its body back into the
+// registered Go type instead of a raw *ExtensionObject.
+func RegisterExtensionObject(typeID *NodeID, factory func() interface{}) {
+	entry := extensionObjectType{typeID: typeID, factory: factory}
+	got := reflect.TypeOf(factory())
+
+	extTypesMu.Lock()
+	defer extTypesMu.Unlock()
+	extTypesByID[typeID.String()] = entry
+	extTypesByGo[got] = entry
+}
+
+func extensionObjectByID(typeID *NodeID) (extensionObjectType, bool) {
+	if typeID == nil {
+		return extensionObjectType{}, false
+	}
+	extTypesMu.RLock()
+	defer extTypesMu.RUnlock()
+	e, ok := extTypesByID[typeID.String()]
+	return e, ok
+}
+
+func extensionObjectByGoType(v interface{}) (extensionObjectType, bool) {
+	extTypesMu.RLock()
+	defer extTypesMu.RUnlock()
+	e, ok := extTypesByGo[reflect.TypeOf(v)]
+	return e, ok
+}
+
+// encodeExtensionValue wraps value, a registered user type, in an
+// ExtensionObject and writes it to buf.
+func encodeExtensionValue(buf *Buffer, value interface{}) error {
+	entry, ok := extensionObjectByGoType(value)
+	if !ok {
+		return fmt.Errorf("opcua: cannot encode variant value of type %T", value)
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+	if enc, ok := value.(BinaryEncoder); ok {
+		body, err = enc.EncodeOPCUA()
+	} else {
+		body, err = encodeStructBody(value)
+	}
+	if err != nil {
+		return err
+	}
+
+	buf.WriteStruct(&ExtensionObject{
+		TypeID: entry.typeID,
+		Value:  body,
+	})
+	return nil
+}
+
+// encodeStructBody encodes v with the generic struct codec, for
+// registered types that only implement the BinaryDecoder marker (or
+// neither interface) and rely on reflection instead of a custom
+// EncodeOPCUA.
+func encodeStructBody(v interface{}) ([]byte, error) {
+	buf := NewBuffer(nil)
+	buf.WriteStruct(v)
+	return buf.Bytes(), buf.Error()
+}
+
+// decodeExtensionObject unwraps eo into the Go type registered for its
+// TypeID, falling back to returning eo unchanged when no type is
+// registered or the body cannot be decoded.
+func decodeExtensionObject(eo *ExtensionObject) interface{} {
+	entry, ok := extensionObjectByID(eo.TypeID)
+	if !ok {
+		return eo
+	}
+
+	body, ok := eo.Value.([]byte)
+	if !ok {
+		return eo
+	}
+
+	target := entry.factory()
+	if dec, ok := target.(BinaryDecoder); ok {
+		if _, err := dec.DecodeOPCUA(body); err != nil {
+			return eo
+		}
+		return target
+	}
+
+	buf := NewBuffer(body)
+	buf.ReadStruct(target)
+	if buf.Error() != nil {
+		return eo
+	}
+	return target
+}
+
+// As unwraps the Variant's Value into target, which must be a non-nil
+// pointer whose pointee type is assignable from the Value's concrete
+// type. This is the usual way to recover a registered extension object's
+// Go value, e.g.:
+//
+//	var telemetry MotorTelemetry
+//	if err := v.As(&telemetry); err != nil { ... }
+func (m *Variant) As(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("opcua: As target must be a non-nil pointer, got %T", target)
+	}
+
+	val := reflect.ValueOf(m.Value)
+	if !val.IsValid() {
+		return fmt.Errorf("opcua: variant has no value to unwrap")
+	}
+	if !val.Type().AssignableTo(rv.Elem().Type()) {
+		return fmt.Errorf("opcua: cannot assign %T to %T", m.Value, target)
+	}
+
+	rv.Elem().Set(val)
+	return nil
+}
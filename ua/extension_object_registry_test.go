@@ -0,0 +1,37 @@
+// Copyright 2018-2019 opcua authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ua
+
+import (
+	"reflect"
+	"testing"
+)
+
+type customTelemetry struct {
+	Value int32
+}
+
+func TestNativeSliceFallsBackOnMixedExtensionObjectTypes(t *testing.T) {
+	values := []interface{}{&customTelemetry{Value: 1}, &ExtensionObject{}}
+
+	got := nativeSlice(TypeExtensionObject, values)
+	if got.Type() != reflect.TypeOf([]interface{}(nil)) {
+		t.Fatalf("nativeSlice() type = %v, want []interface{}", got.Type())
+	}
+	for i, v := range values {
+		if !reflect.DeepEqual(got.Index(i).Interface(), v) {
+			t.Fatalf("nativeSlice()[%d] = %#v, want %#v", i, got.Index(i).Interface(), v)
+		}
+	}
+}
+
+func TestNativeSliceHomogeneousExtensionObjects(t *testing.T) {
+	values := []interface{}{&ExtensionObject{}, &ExtensionObject{}}
+
+	got := nativeSlice(TypeExtensionObject, values)
+	if got.Type() != reflect.TypeOf([]*ExtensionObject(nil)) {
+		t.Fatalf("nativeSlice() type = %v, want []*ExtensionObject", got.Type())
+	}
+}
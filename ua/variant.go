@@ -6,6 +6,8 @@ package ua
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"time"
 )
 
@@ -56,6 +58,61 @@ func NewVariant(v interface{}) (*Variant, error) {
 	return va, nil
 }
 
+// NewVariantArray creates a Variant from a flat, one-dimensional slice of
+// built-in values, reshaping it into a multi-dimensional array when dims
+// has more than one entry. elemType identifies the built-in type of every
+// element in flat, e.g. TypeInt32 for a []int32.
+func NewVariantArray(elemType Type, dims []int32, flat interface{}) (*Variant, error) {
+	rv := reflect.ValueOf(flat)
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("opcua: flat value must be a slice, got %T", flat)
+	}
+
+	n := rv.Len()
+	if want := dimsProduct(dims, n); want != n {
+		return nil, fmt.Errorf("opcua: flat value has %d elements, dimensions require %d", n, want)
+	}
+
+	values := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		v := rv.Index(i).Interface()
+		if t, ok := scalarType(v); !ok || t != elemType {
+			return nil, fmt.Errorf("opcua: flat value element %T does not match element type %d", v, elemType)
+		}
+		values[i] = v
+	}
+
+	m := &Variant{}
+	m.SetType(elemType)
+	m.EncodingMask |= VariantArrayValues
+	m.ArrayLength = int32(n)
+	m.Value = flat
+
+	if len(dims) > 1 {
+		m.EncodingMask |= VariantArrayDimensions
+		m.ArrayDimensionsLength = int32(len(dims))
+		m.ArrayDimensions = dims
+		// flatValues/Encode (and Decode) expect m.Value to be the nested
+		// slice-of-slices reshapeSlice produces whenever ArrayDimensions
+		// has rank > 1, not the flat slice passed in.
+		m.Value = reshapeSlice(rv, dims).Interface()
+	}
+	return m, nil
+}
+
+// dimsProduct returns the product of dims, or n unchanged if dims is empty
+// so that a nil/empty dims argument is treated as "one dimension of n".
+func dimsProduct(dims []int32, n int) int {
+	if len(dims) == 0 {
+		return n
+	}
+	p := 1
+	for _, d := range dims {
+		p *= int(d)
+	}
+	return p
+}
+
 func MustVariant(v interface{}) *Variant {
 	va, err := NewVariant(v)
 	if err != nil {
@@ -77,6 +134,14 @@ func (m *Variant) Has(mask byte) bool {
 }
 
 func (m *Variant) Decode(b []byte) (int, error) {
+	return m.decode(b, 0)
+}
+
+// decode is the depth-tracking implementation behind Decode. depth is the
+// nesting level of m itself, starting at 0 for the outermost call; it is
+// checked against the configured maximum before decoding into a nested
+// Variant or DiagnosticInfo value.
+func (m *Variant) decode(b []byte, depth int) (int, error) {
 	buf := NewBuffer(b)
 
 	m.EncodingMask = buf.ReadByte()
@@ -145,18 +210,22 @@ func (m *Variant) Decode(b []byte) (int, error) {
 		case TypeExtensionObject:
 			v := new(ExtensionObject)
 			buf.ReadStruct(v)
-			values[i] = v
+			values[i] = decodeExtensionObject(v)
 		case TypeDataValue:
 			v := new(DataValue)
 			buf.ReadStruct(v)
 			values[i] = v
 		case TypeVariant:
-			// todo(fs): limit recursion depth to 100
+			if err := CheckDecodeDepth(depth); err != nil {
+				return buf.Pos(), err
+			}
 			v := new(Variant)
-			buf.ReadStruct(v)
+			buf.ReadStruct(&nestedVariant{Variant: v, depth: depth + 1})
 			values[i] = v
 		case TypeDiagnosticInfo:
-			// todo(fs): limit recursion depth to 100
+			if err := CheckDecodeDepth(depth); err != nil {
+				return buf.Pos(), err
+			}
 			v := new(DiagnosticInfo)
 			buf.ReadStruct(v)
 			values[i] = v
@@ -171,14 +240,128 @@ func (m *Variant) Decode(b []byte) (int, error) {
 		}
 	}
 
-	m.Value = values
-	if elems == 1 {
+	switch {
+	case !m.Has(VariantArrayValues):
 		m.Value = values[0]
+	case m.Has(VariantArrayDimensions) && len(m.ArrayDimensions) > 1:
+		flat := nativeSlice(m.Type(), values)
+		m.Value = reshapeSlice(flat, m.ArrayDimensions).Interface()
+	default:
+		m.Value = nativeSlice(m.Type(), values).Interface()
 	}
 
 	return buf.Pos(), buf.Error()
 }
 
+// nativeSlice converts values, a []interface{} holding elements decoded for
+// built-in type t, into a reflect.Value of the equivalent native Go slice
+// type, e.g. []int32 for TypeInt32.
+//
+// An array of TypeExtensionObject is special: decodeExtensionObject may
+// have unwrapped some, but not all, elements into a registered concrete
+// Go type, so the elements aren't guaranteed to share a single type. In
+// that case fall back to a plain []interface{} instead of forcing every
+// element into goElemType(t) (*ExtensionObject), which would panic.
+func nativeSlice(t Type, values []interface{}) reflect.Value {
+	elemType := goElemType(t)
+	if t == TypeExtensionObject && !allAssignableTo(values, elemType) {
+		elemType = reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+
+	flat := reflect.MakeSlice(reflect.SliceOf(elemType), len(values), len(values))
+	for i, v := range values {
+		flat.Index(i).Set(reflect.ValueOf(v))
+	}
+	return flat
+}
+
+// allAssignableTo reports whether every element of values can be assigned
+// to a variable of type t.
+func allAssignableTo(values []interface{}, t reflect.Type) bool {
+	for _, v := range values {
+		if v == nil || !reflect.TypeOf(v).AssignableTo(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// reshapeSlice turns the one-dimensional slice flat into the nested
+// slice-of-slices described by dims, e.g. a 6-element flat slice and
+// dims []int32{2,3} becomes a [][]T of shape 2x3.
+func reshapeSlice(flat reflect.Value, dims []int32) reflect.Value {
+	cur := flat
+	for d := len(dims) - 1; d >= 1; d-- {
+		n := int(dims[d])
+		groups := cur.Len() / n
+		out := reflect.MakeSlice(reflect.SliceOf(cur.Type()), groups, groups)
+		for i := 0; i < groups; i++ {
+			out.Index(i).Set(cur.Slice(i*n, (i+1)*n))
+		}
+		cur = out
+	}
+	return cur
+}
+
+// goElemType returns the native Go type used to represent a single element
+// of the built-in type t.
+func goElemType(t Type) reflect.Type {
+	switch t {
+	case TypeBoolean:
+		return reflect.TypeOf(false)
+	case TypeSByte:
+		return reflect.TypeOf(int8(0))
+	case TypeByte:
+		return reflect.TypeOf(byte(0))
+	case TypeInt16:
+		return reflect.TypeOf(int16(0))
+	case TypeUint16:
+		return reflect.TypeOf(uint16(0))
+	case TypeInt32:
+		return reflect.TypeOf(int32(0))
+	case TypeUint32:
+		return reflect.TypeOf(uint32(0))
+	case TypeInt64:
+		return reflect.TypeOf(int64(0))
+	case TypeUint64:
+		return reflect.TypeOf(uint64(0))
+	case TypeFloat:
+		return reflect.TypeOf(float32(0))
+	case TypeDouble:
+		return reflect.TypeOf(float64(0))
+	case TypeString:
+		return reflect.TypeOf("")
+	case TypeDateTime:
+		return reflect.TypeOf(time.Time{})
+	case TypeGUID:
+		return reflect.TypeOf((*GUID)(nil))
+	case TypeByteString:
+		return reflect.TypeOf([]byte(nil))
+	case TypeXMLElement:
+		return reflect.TypeOf(XmlElement(""))
+	case TypeNodeID:
+		return reflect.TypeOf((*NodeID)(nil))
+	case TypeExpandedNodeID:
+		return reflect.TypeOf((*ExpandedNodeID)(nil))
+	case TypeStatusCode:
+		return reflect.TypeOf(StatusCode(0))
+	case TypeQualifiedName:
+		return reflect.TypeOf((*QualifiedName)(nil))
+	case TypeLocalizedText:
+		return reflect.TypeOf((*LocalizedText)(nil))
+	case TypeExtensionObject:
+		return reflect.TypeOf((*ExtensionObject)(nil))
+	case TypeDataValue:
+		return reflect.TypeOf((*DataValue)(nil))
+	case TypeVariant:
+		return reflect.TypeOf((*Variant)(nil))
+	case TypeDiagnosticInfo:
+		return reflect.TypeOf((*DiagnosticInfo)(nil))
+	default:
+		return reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+}
+
 func (m *Variant) Encode() ([]byte, error) {
 	buf := NewBuffer(nil)
 
@@ -186,9 +369,79 @@ func (m *Variant) Encode() ([]byte, error) {
 
 	if m.Has(VariantArrayValues) {
 		buf.WriteInt32(m.ArrayLength)
+
+		flat, err := m.flatValues()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range flat {
+			if err := encodeValue(buf, v); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if err := encodeValue(buf, m.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.Has(VariantArrayDimensions) {
+		buf.WriteInt32(m.ArrayDimensionsLength)
+		for i := 0; i < int(m.ArrayDimensionsLength); i++ {
+			buf.WriteInt32(m.ArrayDimensions[i])
+		}
+	}
+
+	return buf.Bytes(), buf.Error()
+}
+
+// flatValues returns m.Value as a one-dimensional []interface{}, flattening
+// the nested slice-of-slices produced when ArrayDimensions describes a
+// multi-dimensional array.
+func (m *Variant) flatValues() ([]interface{}, error) {
+	if v, ok := m.Value.([]interface{}); ok {
+		return v, nil
+	}
+
+	rank := 1
+	if m.Has(VariantArrayDimensions) {
+		rank = len(m.ArrayDimensions)
+	}
+
+	rv := reflect.ValueOf(m.Value)
+	return flattenLevels(rv, rank-1)
+}
+
+// flattenLevels descends levels slice-of-slice layers into rv and returns
+// the elements found at that depth as a flat []interface{}.
+func flattenLevels(rv reflect.Value, levels int) ([]interface{}, error) {
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("opcua: cannot encode variant array value of type %v", rv.Type())
+	}
+	if levels == 0 {
+		flat := make([]interface{}, rv.Len())
+		for i := range flat {
+			flat[i] = rv.Index(i).Interface()
+		}
+		return flat, nil
+	}
+
+	var flat []interface{}
+	for i := 0; i < rv.Len(); i++ {
+		sub, err := flattenLevels(rv.Index(i), levels-1)
+		if err != nil {
+			return nil, err
+		}
+		flat = append(flat, sub...)
 	}
+	return flat, nil
+}
 
-	switch v := m.Value.(type) {
+// encodeValue writes a single scalar built-in value to buf, wrapping a
+// registered user type in an ExtensionObject if value isn't one of the
+// built-ins.
+func encodeValue(buf *Buffer, value interface{}) error {
+	switch v := value.(type) {
 	case bool:
 		buf.WriteBool(v)
 	case int8:
@@ -239,137 +492,288 @@ func (m *Variant) Encode() ([]byte, error) {
 		buf.WriteStruct(v)
 	case *DiagnosticInfo:
 		buf.WriteStruct(v)
+	default:
+		return encodeExtensionValue(buf, value)
 	}
-
-	if m.Has(VariantArrayDimensions) {
-		buf.WriteInt32(m.ArrayDimensionsLength)
-		for i := 0; i < int(m.ArrayDimensionsLength); i++ {
-			buf.WriteInt32(m.ArrayDimensions[i])
-		}
-	}
-
-	return buf.Bytes(), buf.Error()
+	return nil
 }
 
-func (m *Variant) Set(v interface{}) error {
+// scalarType returns the built-in Type for a single scalar Go value and
+// whether v is a recognised built-in at all.
+func scalarType(v interface{}) (Type, bool) {
 	switch v.(type) {
 	case bool:
-		m.SetType(TypeBoolean)
+		return TypeBoolean, true
 	case int8:
-		m.SetType(TypeSByte)
+		return TypeSByte, true
 	case byte:
-		m.SetType(TypeByte)
+		return TypeByte, true
 	case int16:
-		m.SetType(TypeInt16)
+		return TypeInt16, true
 	case uint16:
-		m.SetType(TypeUint16)
+		return TypeUint16, true
 	case int32:
-		m.SetType(TypeInt32)
+		return TypeInt32, true
 	case uint32:
-		m.SetType(TypeUint32)
+		return TypeUint32, true
 	case int64:
-		m.SetType(TypeInt64)
+		return TypeInt64, true
 	case uint64:
-		m.SetType(TypeUint64)
+		return TypeUint64, true
 	case float32:
-		m.SetType(TypeFloat)
+		return TypeFloat, true
 	case float64:
-		m.SetType(TypeDouble)
+		return TypeDouble, true
 	case string:
-		m.SetType(TypeString)
+		return TypeString, true
 	case time.Time:
-		m.SetType(TypeDateTime)
+		return TypeDateTime, true
 	case *GUID:
-		m.SetType(TypeGUID)
+		return TypeGUID, true
 	case []byte:
-		m.SetType(TypeByteString)
+		return TypeByteString, true
 	case XmlElement:
-		m.SetType(TypeXMLElement)
+		return TypeXMLElement, true
 	case *NodeID:
-		m.SetType(TypeNodeID)
+		return TypeNodeID, true
 	case *ExpandedNodeID:
-		m.SetType(TypeExpandedNodeID)
+		return TypeExpandedNodeID, true
 	case StatusCode:
-		m.SetType(TypeStatusCode)
+		return TypeStatusCode, true
 	case *QualifiedName:
-		m.SetType(TypeQualifiedName)
+		return TypeQualifiedName, true
 	case *LocalizedText:
-		m.SetType(TypeLocalizedText)
+		return TypeLocalizedText, true
 	case *ExtensionObject:
-		m.SetType(TypeExtensionObject)
+		return TypeExtensionObject, true
 	case *DataValue:
-		m.SetType(TypeDataValue)
+		return TypeDataValue, true
 	case *Variant:
-		m.SetType(TypeVariant)
+		return TypeVariant, true
 	case *DiagnosticInfo:
-		m.SetType(TypeDiagnosticInfo)
+		return TypeDiagnosticInfo, true
 	default:
+		return 0, false
+	}
+}
+
+func (m *Variant) Set(v interface{}) error {
+	if t, ok := scalarType(v); ok {
+		m.SetType(t)
+		m.Value = v
+		return nil
+	}
+
+	if _, ok := extensionObjectByGoType(v); ok {
+		m.SetType(TypeExtensionObject)
+		m.Value = v
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
 		return fmt.Errorf("opcua: cannot set variant to %T", v)
 	}
-	m.Value = v
+
+	elemType, dims, flat, err := flattenArray(rv)
+	if err != nil {
+		return err
+	}
+
+	m.SetType(elemType)
+	m.EncodingMask |= VariantArrayValues
+	m.ArrayLength = int32(len(flat))
+	// Hold the same native slice (or slice-of-slices) that Decode produces.
+	native := nativeSlice(elemType, flat)
+	m.Value = native.Interface()
+
+	if len(dims) > 1 {
+		m.EncodingMask |= VariantArrayDimensions
+		m.ArrayDimensionsLength = int32(len(dims))
+		m.ArrayDimensions = dims
+		if len(flat) > 0 {
+			m.Value = reshapeSlice(native, dims).Interface()
+		}
+	}
 	return nil
 }
 
+// flattenArray walks a (possibly multi-dimensional) slice rv and returns
+// the built-in type of its elements, the size of each dimension, and the
+// elements flattened into a single one-dimensional slice.
+func flattenArray(rv reflect.Value) (Type, []int32, []interface{}, error) {
+	// []byte is itself the native Go representation of a scalar ByteString
+	// (see goElemType), not a dimension to descend into - an array of
+	// ByteStrings is [][]byte, a rank-1 array whose elements happen to be
+	// slices, not a rank-2 array of Byte.
+	byteSliceType := reflect.TypeOf([]byte(nil))
+
+	var dims []int32
+	for v := rv; v.Kind() == reflect.Slice && v.Type() != byteSliceType; {
+		dims = append(dims, int32(v.Len()))
+		if v.Len() == 0 {
+			break
+		}
+		v = v.Index(0)
+	}
+
+	var (
+		elemType Type
+		typeSet  bool
+		flat     []interface{}
+	)
+
+	var walk func(v reflect.Value, depth int) error
+	walk = func(v reflect.Value, depth int) error {
+		if depth == len(dims) {
+			elem := v.Interface()
+			t, ok := scalarType(elem)
+			if !ok {
+				if _, ok2 := extensionObjectByGoType(elem); ok2 {
+					t, ok = TypeExtensionObject, true
+				}
+			}
+			if !ok {
+				return fmt.Errorf("opcua: cannot set variant to %v containing %T", rv.Interface(), elem)
+			}
+			if !typeSet {
+				elemType, typeSet = t, true
+			} else if t != elemType {
+				return fmt.Errorf("opcua: array elements must have the same type, got %T and expected type %d", elem, elemType)
+			}
+			flat = append(flat, elem)
+			return nil
+		}
+		if int32(v.Len()) != dims[depth] {
+			return fmt.Errorf("opcua: array is not rectangular at dimension %d", depth)
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := walk(v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(rv, 0); err != nil {
+		return 0, nil, nil, err
+	}
+	return elemType, dims, flat, nil
+}
+
 func (m *Variant) String() string {
+	if s, ok := m.TryString(); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", m.Value)
+}
+
+// TryString returns the variant's value as a string and true if the
+// variant holds a String, LocalizedText or QualifiedName.
+func (m *Variant) TryString() (string, bool) {
 	switch m.Type() {
 	case TypeString:
-		return m.Value.(string)
+		return m.Value.(string), true
 	case TypeLocalizedText:
-		return m.Value.(*LocalizedText).Text
+		return m.Value.(*LocalizedText).Text, true
 	case TypeQualifiedName:
-		return m.Value.(*QualifiedName).Name
+		return m.Value.(*QualifiedName).Name, true
 	default:
-		return fmt.Sprintf("%v", m.Value)
+		return "", false
 	}
 }
 
 func (m *Variant) Bool() bool {
+	b, _ := m.TryBool()
+	return b
+}
+
+// TryBool returns the variant's value as a bool and true if the variant
+// holds a Boolean.
+func (m *Variant) TryBool() (bool, bool) {
 	switch m.Type() {
 	case TypeBoolean:
-		return m.Value.(bool)
+		return m.Value.(bool), true
 	default:
-		return false
+		return false, false
 	}
 }
 
 func (m *Variant) Float() float64 {
+	f, _ := m.TryFloat()
+	return f
+}
+
+// TryFloat returns the variant's value as a float64 and true if the
+// variant holds a Float or Double.
+func (m *Variant) TryFloat() (float64, bool) {
 	switch m.Type() {
 	case TypeFloat:
-		return float64(m.Value.(float32))
+		return float64(m.Value.(float32)), true
 	case TypeDouble:
-		return m.Value.(float64)
+		return m.Value.(float64), true
 	default:
-		return 0
+		return 0, false
 	}
 }
 
 func (m *Variant) Int() int64 {
+	i, _ := m.TryInt()
+	return i
+}
+
+// TryInt returns the variant's value widened to an int64 and true if the
+// variant holds a signed or unsigned integer type.
+func (m *Variant) TryInt() (int64, bool) {
 	switch m.Type() {
 	case TypeSByte:
-		return int64(m.Value.(int8))
+		return int64(m.Value.(int8)), true
+	case TypeByte:
+		return int64(m.Value.(byte)), true
+	case TypeInt16:
+		return int64(m.Value.(int16)), true
 	case TypeUint16:
-		return int64(m.Value.(int16))
+		return int64(m.Value.(uint16)), true
+	case TypeInt32:
+		return int64(m.Value.(int32)), true
 	case TypeUint32:
-		return int64(m.Value.(int32))
+		return int64(m.Value.(uint32)), true
+	case TypeInt64:
+		return m.Value.(int64), true
 	case TypeUint64:
-		return m.Value.(int64)
+		return int64(m.Value.(uint64)), true
 	default:
-		return 0
+		return 0, false
 	}
 }
 
 func (m *Variant) Uint() uint64 {
+	u, _ := m.TryUint()
+	return u
+}
+
+// TryUint returns the variant's value widened to a uint64 and true if the
+// variant holds a signed or unsigned integer type.
+func (m *Variant) TryUint() (uint64, bool) {
 	switch m.Type() {
+	case TypeSByte:
+		return uint64(m.Value.(int8)), true
 	case TypeByte:
-		return uint64(m.Value.(byte))
+		return uint64(m.Value.(byte)), true
+	case TypeInt16:
+		return uint64(m.Value.(int16)), true
 	case TypeUint16:
-		return uint64(m.Value.(uint16))
+		return uint64(m.Value.(uint16)), true
+	case TypeInt32:
+		return uint64(m.Value.(int32)), true
 	case TypeUint32:
-		return uint64(m.Value.(uint32))
+		return uint64(m.Value.(uint32)), true
+	case TypeInt64:
+		return uint64(m.Value.(int64)), true
 	case TypeUint64:
-		return m.Value.(uint64)
+		return m.Value.(uint64), true
 	default:
-		return 0
+		return 0, false
 	}
 }
 
@@ -381,3 +785,184 @@ func (m *Variant) Time() time.Time {
 		return time.Time{}
 	}
 }
+
+// opcuaEpoch is the start of the OPC UA DateTime encoding: 00:00:00 UTC on
+// 1 January 1601, counted in 100ns ticks.
+var opcuaEpoch = time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func timeToTicks(t time.Time) int64 {
+	return (t.Unix()-opcuaEpoch.Unix())*1e7 + int64(t.Nanosecond()/100)
+}
+
+func ticksToTime(ticks int64) time.Time {
+	return time.Unix(opcuaEpoch.Unix()+ticks/1e7, (ticks%1e7)*100).UTC()
+}
+
+// ConvertTo converts the variant's value to the built-in type t following
+// the implicit/explicit conversion rules of OPC UA Part 4 §7.4: numeric
+// widening and narrowing, Boolean<->numeric, String parsing/formatting,
+// StatusCode<->UInt32, and DateTime<->Int64 ticks. It returns a new
+// Variant and leaves m unchanged.
+func (m *Variant) ConvertTo(t Type) (*Variant, error) {
+	if m.Type() == t {
+		return NewVariant(m.Value)
+	}
+
+	switch t {
+	case TypeBoolean:
+		return m.convertToBool()
+	case TypeSByte, TypeByte, TypeInt16, TypeUint16, TypeInt32, TypeUint32, TypeInt64, TypeUint64:
+		return m.convertToInt(t)
+	case TypeFloat, TypeDouble:
+		return m.convertToFloat(t)
+	case TypeString:
+		return m.convertToString()
+	case TypeStatusCode:
+		return m.convertToStatusCode()
+	case TypeDateTime:
+		return m.convertToDateTime()
+	default:
+		return nil, fmt.Errorf("opcua: cannot convert %v to %v", m.Type(), t)
+	}
+}
+
+func (m *Variant) convertToBool() (*Variant, error) {
+	switch m.Type() {
+	case TypeString:
+		switch s := m.Value.(string); s {
+		case "true", "1":
+			return NewVariant(true)
+		case "false", "0":
+			return NewVariant(false)
+		default:
+			return nil, fmt.Errorf("opcua: cannot convert %q to Boolean", s)
+		}
+	default:
+		if i, ok := m.TryInt(); ok {
+			return NewVariant(i != 0)
+		}
+		if f, ok := m.TryFloat(); ok {
+			return NewVariant(f != 0)
+		}
+		return nil, fmt.Errorf("opcua: cannot convert %v to Boolean", m.Type())
+	}
+}
+
+func (m *Variant) convertToInt(t Type) (*Variant, error) {
+	var n int64
+	switch {
+	case m.Type() == TypeBoolean:
+		if m.Value.(bool) {
+			n = 1
+		}
+	case m.Type() == TypeString:
+		v, err := strconv.ParseInt(m.Value.(string), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("opcua: cannot convert %q to %v: %w", m.Value, t, err)
+		}
+		n = v
+	case m.Type() == TypeStatusCode:
+		n = int64(uint32(m.Value.(StatusCode)))
+	case m.Type() == TypeDateTime:
+		n = timeToTicks(m.Value.(time.Time))
+	default:
+		if i, ok := m.TryInt(); ok {
+			n = i
+		} else if f, ok := m.TryFloat(); ok {
+			n = int64(f)
+		} else {
+			return nil, fmt.Errorf("opcua: cannot convert %v to %v", m.Type(), t)
+		}
+	}
+
+	switch t {
+	case TypeSByte:
+		return NewVariant(int8(n))
+	case TypeByte:
+		return NewVariant(byte(n))
+	case TypeInt16:
+		return NewVariant(int16(n))
+	case TypeUint16:
+		return NewVariant(uint16(n))
+	case TypeInt32:
+		return NewVariant(int32(n))
+	case TypeUint32:
+		return NewVariant(uint32(n))
+	case TypeInt64:
+		return NewVariant(n)
+	case TypeUint64:
+		return NewVariant(uint64(n))
+	default:
+		return nil, fmt.Errorf("opcua: %v is not an integer type", t)
+	}
+}
+
+func (m *Variant) convertToFloat(t Type) (*Variant, error) {
+	var f float64
+	switch {
+	case m.Type() == TypeBoolean:
+		if m.Value.(bool) {
+			f = 1
+		}
+	case m.Type() == TypeString:
+		v, err := strconv.ParseFloat(m.Value.(string), 64)
+		if err != nil {
+			return nil, fmt.Errorf("opcua: cannot convert %q to %v: %w", m.Value, t, err)
+		}
+		f = v
+	default:
+		if v, ok := m.TryFloat(); ok {
+			f = v
+		} else if i, ok := m.TryInt(); ok {
+			f = float64(i)
+		} else {
+			return nil, fmt.Errorf("opcua: cannot convert %v to %v", m.Type(), t)
+		}
+	}
+
+	switch t {
+	case TypeFloat:
+		return NewVariant(float32(f))
+	case TypeDouble:
+		return NewVariant(f)
+	default:
+		return nil, fmt.Errorf("opcua: %v is not a floating point type", t)
+	}
+}
+
+func (m *Variant) convertToString() (*Variant, error) {
+	switch m.Type() {
+	case TypeDateTime:
+		return NewVariant(m.Value.(time.Time).UTC().Format(time.RFC3339Nano))
+	case TypeStatusCode:
+		return NewVariant(fmt.Sprintf("0x%08X", uint32(m.Value.(StatusCode))))
+	default:
+		return NewVariant(m.String())
+	}
+}
+
+func (m *Variant) convertToStatusCode() (*Variant, error) {
+	switch m.Type() {
+	case TypeUint32:
+		return NewVariant(StatusCode(m.Value.(uint32)))
+	default:
+		if u, ok := m.TryUint(); ok {
+			return NewVariant(StatusCode(u))
+		}
+		return nil, fmt.Errorf("opcua: cannot convert %v to StatusCode", m.Type())
+	}
+}
+
+func (m *Variant) convertToDateTime() (*Variant, error) {
+	switch m.Type() {
+	case TypeInt64:
+		return NewVariant(ticksToTime(m.Value.(int64)))
+	case TypeUint64:
+		return NewVariant(ticksToTime(int64(m.Value.(uint64))))
+	default:
+		if i, ok := m.TryInt(); ok {
+			return NewVariant(ticksToTime(i))
+		}
+		return nil, fmt.Errorf("opcua: cannot convert %v to DateTime", m.Type())
+	}
+}
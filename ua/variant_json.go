@@ -0,0 +1,239 @@
+// Copyright 2018-2019 opcua authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ua
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// MarshalJSONOptions controls how Variant encodes itself to JSON.
+type MarshalJSONOptions struct {
+	// Reversible selects the reversible JSON encoding from OPC UA Part 6
+	// §5.4.2, which round-trips losslessly through Decode/Encode. When
+	// false, the compact, lossy, non-reversible encoding from §5.4.3 is
+	// produced instead.
+	Reversible bool
+}
+
+// reversibleVariant is the wire shape of the reversible JSON encoding.
+type reversibleVariant struct {
+	Type       int         `json:"Type"`
+	Body       interface{} `json:"Body"`
+	Dimensions []int32     `json:"Dimensions,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler using the reversible encoding.
+// Use MarshalJSONOpts to request the non-reversible form.
+func (m *Variant) MarshalJSON() ([]byte, error) {
+	return m.MarshalJSONOpts(MarshalJSONOptions{Reversible: true})
+}
+
+// MarshalJSONOpts encodes the Variant as JSON per OPC UA Part 6 §5.4,
+// choosing the reversible or non-reversible form based on opts.Reversible.
+func (m *Variant) MarshalJSONOpts(opts MarshalJSONOptions) ([]byte, error) {
+	value := m.Value
+	if m.Has(VariantArrayValues) {
+		// m.Value may be a nested slice-of-slices for a multi-dimensional
+		// array (see reshapeSlice); the wire body is always the flat
+		// array, with shape carried separately in Dimensions.
+		flat, err := m.flatValues()
+		if err != nil {
+			return nil, err
+		}
+		value = flat
+	}
+
+	body, err := jsonValue(value, opts.Reversible)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Reversible {
+		return json.Marshal(body)
+	}
+
+	return json.Marshal(reversibleVariant{
+		Type:       int(m.Type()),
+		Body:       body,
+		Dimensions: m.ArrayDimensions,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler for the reversible encoding
+// produced by MarshalJSON.
+func (m *Variant) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type       int
+		Body       json.RawMessage
+		Dimensions []int32
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	t := Type(raw.Type)
+
+	if len(raw.Dimensions) > 1 {
+		flat, err := unmarshalFlatArray(t, raw.Body)
+		if err != nil {
+			return err
+		}
+		va, err := NewVariantArray(t, raw.Dimensions, flat)
+		if err != nil {
+			return err
+		}
+		*m = *va
+		return nil
+	}
+
+	// A JSON array body always means an array-valued Variant: a scalar
+	// ByteString is encoded by encoding/json as a base64 string, not an
+	// array, so this check alone already distinguishes the two without
+	// needing to special-case TypeByteString.
+	if isJSONArray(raw.Body) {
+		flat, err := unmarshalFlatArray(t, raw.Body)
+		if err != nil {
+			return err
+		}
+		return m.Set(flat)
+	}
+
+	v, err := unmarshalScalar(t, raw.Body)
+	if err != nil {
+		return err
+	}
+	return m.Set(v)
+}
+
+// jsonValue converts a Variant value (scalar, native slice, or nested
+// slice-of-slices) into a value json.Marshal can encode per Part 6 §5.4.
+func jsonValue(v interface{}, reversible bool) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	if _, ok := v.([]byte); !ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+			out := make([]interface{}, rv.Len())
+			for i := range out {
+				ev, err := jsonValue(rv.Index(i).Interface(), reversible)
+				if err != nil {
+					return nil, err
+				}
+				out[i] = ev
+			}
+			return out, nil
+		}
+	}
+
+	switch val := v.(type) {
+	case bool, int8, byte, int16, uint16, int32, uint32, float32, float64, string, []byte:
+		return val, nil
+	case int64:
+		// Part 6 §5.4.2.3: Int64/UInt64 are encoded as strings because
+		// they can exceed the precision of a JSON/JavaScript number.
+		return fmt.Sprintf("%d", val), nil
+	case uint64:
+		return fmt.Sprintf("%d", val), nil
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano), nil
+	case XmlElement:
+		return string(val), nil
+	case StatusCode:
+		if reversible {
+			return uint32(val), nil
+		}
+		return statusCodeJSON{Code: uint32(val), Symbol: val.Error()}, nil
+	case *LocalizedText:
+		if reversible {
+			return val, nil
+		}
+		return val.Text, nil
+	case *QualifiedName:
+		if reversible {
+			return val, nil
+		}
+		return val.Name, nil
+	case *NodeID, *ExpandedNodeID:
+		if reversible {
+			return val, nil
+		}
+		return fmt.Sprintf("%v", val), nil
+	case *GUID, *ExtensionObject, *DataValue, *Variant, *DiagnosticInfo:
+		return val, nil
+	default:
+		return nil, fmt.Errorf("opcua: cannot encode %T to JSON", v)
+	}
+}
+
+// statusCodeJSON is the non-reversible JSON shape for a StatusCode.
+type statusCodeJSON struct {
+	Code   uint32 `json:"Code"`
+	Symbol string `json:"Symbol"`
+}
+
+// unmarshalScalar decodes data, a single JSON value, into the native Go
+// type for the built-in type t.
+func unmarshalScalar(t Type, data json.RawMessage) (interface{}, error) {
+	// Int64/UInt64 are carried as JSON strings (see jsonValue), so they
+	// need explicit parsing instead of the generic reflect-driven path.
+	switch t {
+	case TypeInt64:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		var v int64
+		if _, err := fmt.Sscan(s, &v); err != nil {
+			return nil, fmt.Errorf("opcua: invalid Int64 %q: %w", s, err)
+		}
+		return v, nil
+	case TypeUint64:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		var v uint64
+		if _, err := fmt.Sscan(s, &v); err != nil {
+			return nil, fmt.Errorf("opcua: invalid UInt64 %q: %w", s, err)
+		}
+		return v, nil
+	}
+
+	ptr := reflect.New(goElemType(t))
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// unmarshalFlatArray decodes data, a JSON array, into a flat native Go
+// slice of the built-in type t.
+func unmarshalFlatArray(t Type, data json.RawMessage) (interface{}, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(raw))
+	for i, r := range raw {
+		v, err := unmarshalScalar(t, r)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return nativeSlice(t, values).Interface(), nil
+}
+
+// isJSONArray reports whether data is a JSON array.
+func isJSONArray(data json.RawMessage) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
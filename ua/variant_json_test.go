@@ -0,0 +1,61 @@
+// Copyright 2018-2019 opcua authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ua
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVariantJSONMultiDimRoundTrip(t *testing.T) {
+	v, err := NewVariant([][]int32{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatalf("NewVariant() failed: %v", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("MarshalJSON() failed: %v", err)
+	}
+
+	var got Variant
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("UnmarshalJSON() failed: %v", err)
+	}
+
+	want, err := v.Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	gotBytes, err := got.Encode()
+	if err != nil {
+		t.Fatalf("round-tripped Encode() failed: %v", err)
+	}
+	if !bytes.Equal(gotBytes, want) {
+		t.Fatalf("round-tripped Encode() = %x, want %x", gotBytes, want)
+	}
+}
+
+func TestVariantJSONByteStringArrayRoundTrip(t *testing.T) {
+	v, err := NewVariant([][]byte{{1, 2}, {3, 4, 5}})
+	if err != nil {
+		t.Fatalf("NewVariant() failed: %v", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("MarshalJSON() failed: %v", err)
+	}
+
+	var got Variant
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("UnmarshalJSON() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Value, v.Value) {
+		t.Fatalf("UnmarshalJSON() = %#v, want %#v", got.Value, v.Value)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2018-2019 opcua authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ua
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// nestedVariantChain builds a chain of n Variants, each wrapping the next,
+// with a scalar Int32 at the bottom.
+func nestedVariantChain(n int) *Variant {
+	v := MustVariant(int32(42))
+	for i := 0; i < n; i++ {
+		v = MustVariant(v)
+	}
+	return v
+}
+
+func TestVariantDecodeMaxDepth(t *testing.T) {
+	defer SetMaxDecodeDepth(100)
+	SetMaxDecodeDepth(10)
+
+	b, err := nestedVariantChain(10).Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	var got Variant
+	if _, err := got.Decode(b); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("Decode() error = %v, want ErrMaxDepthExceeded", err)
+	}
+}
+
+func TestVariantDecodeWithinMaxDepth(t *testing.T) {
+	defer SetMaxDecodeDepth(100)
+	SetMaxDecodeDepth(10)
+
+	b, err := nestedVariantChain(9).Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	var got Variant
+	if _, err := got.Decode(b); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+}
+
+func TestVariantArrayRoundTrip(t *testing.T) {
+	v, err := NewVariant([][]int32{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatalf("NewVariant() failed: %v", err)
+	}
+
+	b, err := v.Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	var got Variant
+	if _, err := got.Decode(b); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Value, v.Value) {
+		t.Fatalf("Decode() = %#v, want %#v", got.Value, v.Value)
+	}
+}
+
+func TestVariantByteStringArrayRoundTrip(t *testing.T) {
+	want := [][]byte{{1, 2}, {3, 4, 5}}
+
+	v, err := NewVariant(want)
+	if err != nil {
+		t.Fatalf("NewVariant() failed: %v", err)
+	}
+	if v.Type() != TypeByteString {
+		t.Fatalf("Type() = %v, want TypeByteString", v.Type())
+	}
+
+	b, err := v.Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	var got Variant
+	if _, err := got.Decode(b); err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Value, want) {
+		t.Fatalf("Decode() = %#v, want %#v", got.Value, want)
+	}
+}
+
+func TestNewVariantArrayReshape(t *testing.T) {
+	v, err := NewVariantArray(TypeInt32, []int32{2, 3}, []int32{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatalf("NewVariantArray() failed: %v", err)
+	}
+
+	want := [][]int32{{1, 2, 3}, {4, 5, 6}}
+	if !reflect.DeepEqual(v.Value, want) {
+		t.Fatalf("Value = %#v, want %#v", v.Value, want)
+	}
+
+	if _, err := v.Encode(); err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+}
+
+func TestVariantConvertTo(t *testing.T) {
+	someTime := opcuaEpoch.Add(1234 * 100 * time.Nanosecond)
+
+	tests := []struct {
+		name    string
+		from    interface{}
+		to      Type
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int32 to int64", from: int32(42), to: TypeInt64, want: int64(42)},
+		{name: "int32 to float64", from: int32(42), to: TypeDouble, want: float64(42)},
+		{name: "float64 to int32", from: float64(42.9), to: TypeInt32, want: int32(42)},
+		{name: "byte to double", from: byte(7), to: TypeDouble, want: float64(7)},
+		{name: "bool to int32", from: true, to: TypeInt32, want: int32(1)},
+		{name: "int32 to bool", from: int32(0), to: TypeBoolean, want: false},
+		{name: "string to int32", from: "42", to: TypeInt32, want: int32(42)},
+		{name: "invalid string to int32", from: "not a number", to: TypeInt32, wantErr: true},
+		{name: "int32 to string", from: int32(42), to: TypeString, want: "42"},
+		{name: "uint32 to statuscode", from: uint32(0x80010000), to: TypeStatusCode, want: StatusCode(0x80010000)},
+		{name: "statuscode to uint32", from: StatusCode(0x80010000), to: TypeUint32, want: uint32(0x80010000)},
+		{name: "int64 to datetime", from: int64(1234), to: TypeDateTime, want: someTime},
+		{name: "datetime to int64", from: someTime, to: TypeInt64, want: int64(1234)},
+		{name: "int32 to int32 (identity)", from: int32(42), to: TypeInt32, want: int32(42)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := MustVariant(tt.from)
+			got, err := v.ConvertTo(tt.to)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ConvertTo(%v) error = nil, want error", tt.to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertTo(%v) failed: %v", tt.to, err)
+			}
+			if !reflect.DeepEqual(got.Value, tt.want) {
+				t.Fatalf("ConvertTo(%v) = %#v, want %#v", tt.to, got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantTryInt(t *testing.T) {
+	if got, ok := MustVariant(uint16(300)).TryInt(); !ok || got != 300 {
+		t.Fatalf("TryInt() = (%v, %v), want (300, true)", got, ok)
+	}
+	if _, ok := MustVariant("not an int").TryInt(); ok {
+		t.Fatalf("TryInt() ok = true, want false for a String variant")
+	}
+}